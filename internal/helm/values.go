@@ -0,0 +1,362 @@
+// Package helm builds the typed helm values consumed by the HCS agent
+// helm config data sources. It mirrors the subset of the consul-k8s helm
+// chart's values.yaml surface that HCS can populate on a caller's behalf.
+package helm
+
+// HelmValues is the root of the consul-k8s helm chart values this package
+// knows how to build. Fields are pointers so that a section is omitted
+// entirely from the rendered YAML/JSON when it isn't configured, matching
+// how the chart treats absent values.
+type HelmValues struct {
+	Global          *Global          `json:"global,omitempty"`
+	ExternalServers *ExternalServers `json:"externalServers,omitempty"`
+	Client          *Client          `json:"client,omitempty"`
+	ConnectInject   *ConnectInject   `json:"connectInject,omitempty"`
+}
+
+// Global mirrors the chart's top-level `global` values.
+type Global struct {
+	Enabled          bool              `json:"enabled"`
+	Name             string            `json:"name,omitempty"`
+	Datacenter       string            `json:"datacenter,omitempty"`
+	ACLs             *ACLs             `json:"acls,omitempty"`
+	GossipEncryption *SecretKeyRef     `json:"gossipEncryption,omitempty"`
+	TLS              *TLS              `json:"tls,omitempty"`
+	AdminPartitions  *AdminPartitions  `json:"adminPartitions,omitempty"`
+	Metrics          *Metrics          `json:"metrics,omitempty"`
+	TransparentProxy *TransparentProxy `json:"transparentProxy,omitempty"`
+	Audit            *Audit            `json:"audit,omitempty"`
+}
+
+// ACLs mirrors `global.acls`.
+type ACLs struct {
+	ManageSystemACLs bool          `json:"manageSystemACLs"`
+	BootstrapToken   *SecretKeyRef `json:"bootstrapToken,omitempty"`
+}
+
+// SecretKeyRef points at a key within a Kubernetes Secret, the shape used
+// throughout the chart for `global.gossipEncryption`, `global.tls.caCert`,
+// and `global.acls.bootstrapToken`.
+type SecretKeyRef struct {
+	SecretName string `json:"secretName,omitempty"`
+	SecretKey  string `json:"secretKey,omitempty"`
+}
+
+// TLS mirrors `global.tls`.
+type TLS struct {
+	Enabled           bool          `json:"enabled"`
+	EnableAutoEncrypt bool          `json:"enableAutoEncrypt,omitempty"`
+	CACert            *SecretKeyRef `json:"caCert,omitempty"`
+}
+
+// AdminPartitions mirrors `global.adminPartitions`.
+type AdminPartitions struct {
+	Enabled bool   `json:"enabled"`
+	Name    string `json:"name,omitempty"`
+}
+
+// Metrics mirrors `global.metrics`.
+type Metrics struct {
+	Enabled            bool `json:"enabled"`
+	EnableAgentMetrics bool `json:"enableAgentMetrics,omitempty"`
+}
+
+// TransparentProxy mirrors `global.transparentProxy`.
+type TransparentProxy struct {
+	DefaultEnabled bool `json:"defaultEnabled"`
+}
+
+// Audit mirrors `global.audit`, the enterprise audit-log values.
+type Audit struct {
+	Enabled bool        `json:"enabled"`
+	Sinks   []AuditSink `json:"sinks,omitempty"`
+}
+
+// AuditSink is a single entry in `global.audit.sinks`.
+type AuditSink struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Format string `json:"format"`
+	Path   string `json:"path,omitempty"`
+}
+
+// ExternalServers mirrors the chart's top-level `externalServers` values.
+type ExternalServers struct {
+	Enabled           bool     `json:"enabled"`
+	Hosts             []string `json:"hosts,omitempty"`
+	HTTPSPort         int      `json:"httpsPort,omitempty"`
+	UseSystemRoots    bool     `json:"useSystemRoots,omitempty"`
+	K8sAuthMethodHost string   `json:"k8sAuthMethodHost,omitempty"`
+}
+
+// Client mirrors the chart's top-level `client` values.
+type Client struct {
+	Enabled bool     `json:"enabled"`
+	Join    []string `json:"join,omitempty"`
+}
+
+// ConnectInject mirrors the chart's top-level `connectInject` values.
+type ConnectInject struct {
+	Enabled          bool              `json:"enabled"`
+	ConsulNamespaces *ConsulNamespaces `json:"consulNamespaces,omitempty"`
+}
+
+// ConsulNamespaces mirrors `connectInject.consulNamespaces`.
+type ConsulNamespaces struct {
+	ConsulDestinationNamespace string `json:"consulDestinationNamespace,omitempty"`
+	MirroringK8S               bool   `json:"mirroringK8S,omitempty"`
+}
+
+// FederationHelmValues is the helm values snippet a secondary cluster
+// needs in order to federate with an HCS-managed primary via mesh
+// gateways, as opposed to the full HelmValues an AKS agent cluster uses.
+type FederationHelmValues struct {
+	Global      *FederationGlobal `json:"global,omitempty"`
+	MeshGateway *MeshGateway      `json:"meshGateway,omitempty"`
+}
+
+// FederationGlobal mirrors the `global` values relevant to federation,
+// including the refs pointing at the keys of the federation Secret
+// (produced alongside these values) that a secondary datacenter needs to
+// come up: the primary's CA cert, gossip encryption key, and (if ACLs are
+// enabled) ACL replication token.
+type FederationGlobal struct {
+	Federation       *Federation     `json:"federation,omitempty"`
+	TLS              *TLS            `json:"tls,omitempty"`
+	GossipEncryption *SecretKeyRef   `json:"gossipEncryption,omitempty"`
+	ACLs             *FederationACLs `json:"acls,omitempty"`
+}
+
+// FederationACLs mirrors the subset of `global.acls` a secondary
+// datacenter needs: the ACL replication token ref.
+type FederationACLs struct {
+	ReplicationToken *SecretKeyRef `json:"replicationToken,omitempty"`
+}
+
+// Federation mirrors `global.federation`.
+type Federation struct {
+	Enabled                bool     `json:"enabled"`
+	CreateFederationSecret bool     `json:"createFederationSecret"`
+	PrimaryDatacenter      string   `json:"primaryDatacenter,omitempty"`
+	K8sAuthMethodHost      string   `json:"k8sAuthMethodHost,omitempty"`
+	PrimaryGateways        []string `json:"primaryGateways,omitempty"`
+}
+
+// MeshGateway mirrors the chart's top-level `meshGateway` values.
+type MeshGateway struct {
+	Enabled bool `json:"enabled"`
+}
+
+// FederationConfig holds the inputs needed to build the
+// FederationHelmValues for a secondary datacenter.
+type FederationConfig struct {
+	// Name is used, lowercased by the caller, to derive the name of the
+	// federation Secret (<name>-federation) that TLS/GossipEncryption/ACLs
+	// below are pointed at.
+	Name              string
+	PrimaryDatacenter string
+	// K8sAuthMethodHost is the primary datacenter's Kubernetes API server
+	// endpoint used for the k8s auth method. Left empty (and omitted from
+	// the rendered values) when it isn't known.
+	K8sAuthMethodHost string
+	PrimaryGateways   []string
+}
+
+// BuildFederationValues builds the FederationHelmValues for a secondary
+// cluster joining federation with an HCS-managed primary, pointing
+// global.tls.caCert, global.gossipEncryption, and global.acls.replicationToken
+// at the federation Secret produced alongside these values.
+func BuildFederationValues(cfg FederationConfig) *FederationHelmValues {
+	secretName := cfg.Name + "-federation"
+
+	return &FederationHelmValues{
+		Global: &FederationGlobal{
+			Federation: &Federation{
+				Enabled:                true,
+				CreateFederationSecret: false,
+				PrimaryDatacenter:      cfg.PrimaryDatacenter,
+				K8sAuthMethodHost:      cfg.K8sAuthMethodHost,
+				PrimaryGateways:        cfg.PrimaryGateways,
+			},
+			TLS: &TLS{
+				Enabled: true,
+				CACert: &SecretKeyRef{
+					SecretName: secretName,
+					SecretKey:  "caCert",
+				},
+			},
+			GossipEncryption: &SecretKeyRef{
+				SecretName: secretName,
+				SecretKey:  "gossipEncryptionKey",
+			},
+			ACLs: &FederationACLs{
+				ReplicationToken: &SecretKeyRef{
+					SecretName: secretName,
+					SecretKey:  "replicationToken",
+				},
+			},
+		},
+		MeshGateway: &MeshGateway{Enabled: true},
+	}
+}
+
+// PeeringHelmValues is the helm values snippet needed to join an external
+// Consul cluster to an HCS-managed cluster via cluster peering, as an
+// alternative to the mesh-gateway WAN federation flow FederationHelmValues
+// builds.
+type PeeringHelmValues struct {
+	Global        *PeeringGlobal `json:"global,omitempty"`
+	MeshGateway   *MeshGateway   `json:"meshGateway,omitempty"`
+	ConnectInject *ConnectInject `json:"connectInject,omitempty"`
+}
+
+// PeeringGlobal mirrors the `global` values relevant to cluster peering.
+type PeeringGlobal struct {
+	Peering         *Peering         `json:"peering,omitempty"`
+	AdminPartitions *AdminPartitions `json:"adminPartitions,omitempty"`
+}
+
+// Peering mirrors `global.peering`.
+type Peering struct {
+	Enabled bool `json:"enabled"`
+}
+
+// PeeringConfig holds the inputs needed to build the PeeringHelmValues for
+// a peer joining cluster peering with an HCS-managed cluster.
+type PeeringConfig struct {
+	// Partition scopes the peering connection to an admin partition; sets
+	// global.adminPartitions when non-empty.
+	Partition string
+	// Namespace scopes the peering connection to a Consul namespace; sets
+	// connectInject.consulNamespaces when non-empty.
+	Namespace string
+}
+
+// BuildPeeringValues builds the PeeringHelmValues for a cluster peering
+// with an HCS-managed cluster.
+func BuildPeeringValues(cfg PeeringConfig) *PeeringHelmValues {
+	global := &PeeringGlobal{
+		Peering: &Peering{Enabled: true},
+	}
+	if cfg.Partition != "" {
+		global.AdminPartitions = &AdminPartitions{
+			Enabled: true,
+			Name:    cfg.Partition,
+		}
+	}
+
+	connectInject := &ConnectInject{Enabled: true}
+	if cfg.Namespace != "" {
+		connectInject.ConsulNamespaces = &ConsulNamespaces{
+			ConsulDestinationNamespace: cfg.Namespace,
+		}
+	}
+
+	return &PeeringHelmValues{
+		Global:        global,
+		MeshGateway:   &MeshGateway{Enabled: true},
+		ConnectInject: connectInject,
+	}
+}
+
+// AgentConfig holds the inputs needed to build the HelmValues for an
+// HCS-managed agent's helm config.
+type AgentConfig struct {
+	Name                     string
+	Datacenter               string
+	Fqdn                     string
+	RetryJoin                []string
+	BootstrapTokenSecretName string
+	BootstrapTokenSecretKey  string
+
+	AdminPartitionName             string
+	ConsulNamespace                string
+	MirroringK8S                   bool
+	TransparentProxyDefaultEnabled bool
+	MetricsEnabled                 bool
+	MetricsEnableAgentMetrics      bool
+	AuditLogEnabled                bool
+	AuditLogSinks                  []AuditSink
+}
+
+// BuildAgentValues builds the HelmValues for an AKS cluster joining an
+// HCS-managed cluster as an agent, i.e. the values behind the agent helm
+// config data source's config output, extended with the optional admin partition,
+// namespace, transparent proxy, metrics, and audit log knobs.
+func BuildAgentValues(cfg AgentConfig) *HelmValues {
+	global := &Global{
+		Enabled:    false,
+		Name:       "consul",
+		Datacenter: cfg.Datacenter,
+		ACLs: &ACLs{
+			ManageSystemACLs: true,
+			BootstrapToken: &SecretKeyRef{
+				SecretName: cfg.BootstrapTokenSecretName,
+				SecretKey:  cfg.BootstrapTokenSecretKey,
+			},
+		},
+		GossipEncryption: &SecretKeyRef{
+			SecretName: cfg.Name + "-hcs",
+			SecretKey:  "gossipEncryptionKey",
+		},
+		TLS: &TLS{
+			Enabled:           true,
+			EnableAutoEncrypt: true,
+			CACert: &SecretKeyRef{
+				SecretName: cfg.Name + "-hcs",
+				SecretKey:  "caCert",
+			},
+		},
+	}
+
+	if cfg.AdminPartitionName != "" {
+		global.AdminPartitions = &AdminPartitions{
+			Enabled: true,
+			Name:    cfg.AdminPartitionName,
+		}
+	}
+
+	if cfg.MetricsEnabled {
+		global.Metrics = &Metrics{
+			Enabled:            true,
+			EnableAgentMetrics: cfg.MetricsEnableAgentMetrics,
+		}
+	}
+
+	if cfg.TransparentProxyDefaultEnabled {
+		global.TransparentProxy = &TransparentProxy{
+			DefaultEnabled: true,
+		}
+	}
+
+	if cfg.AuditLogEnabled {
+		global.Audit = &Audit{
+			Enabled: true,
+			Sinks:   cfg.AuditLogSinks,
+		}
+	}
+
+	connectInject := &ConnectInject{Enabled: true}
+	if cfg.ConsulNamespace != "" {
+		connectInject.ConsulNamespaces = &ConsulNamespaces{
+			ConsulDestinationNamespace: cfg.ConsulNamespace,
+			MirroringK8S:               cfg.MirroringK8S,
+		}
+	}
+
+	return &HelmValues{
+		Global: global,
+		ExternalServers: &ExternalServers{
+			Enabled:           true,
+			Hosts:             cfg.RetryJoin,
+			HTTPSPort:         443,
+			UseSystemRoots:    true,
+			K8sAuthMethodHost: "https://" + cfg.Fqdn + ":443",
+		},
+		Client: &Client{
+			Enabled: true,
+			Join:    cfg.RetryJoin,
+		},
+		ConnectInject: connectInject,
+	}
+}
@@ -0,0 +1,142 @@
+package helm
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func marshalYAML(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() returned error: %v", err)
+	}
+	return string(b)
+}
+
+func TestBuildAgentValues(t *testing.T) {
+	cfg := AgentConfig{
+		Name:                     "myapp",
+		Datacenter:               "dc1",
+		Fqdn:                     "myapp.example.com",
+		RetryJoin:                []string{"10.0.0.1", "10.0.0.2"},
+		BootstrapTokenSecretName: "myapp-bootstrap-token",
+		BootstrapTokenSecretKey:  "token",
+	}
+
+	got := marshalYAML(t, BuildAgentValues(cfg))
+
+	for _, want := range []string{
+		"datacenter: dc1",
+		"secretName: myapp-bootstrap-token",
+		"secretKey: token",
+		"secretName: myapp-hcs",
+		"k8sAuthMethodHost: https://myapp.example.com:443",
+		"- 10.0.0.1",
+		"- 10.0.0.2",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("BuildAgentValues() output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestBuildAgentValues_OptionalKnobs(t *testing.T) {
+	cfg := AgentConfig{
+		Name:                           "myapp",
+		AdminPartitionName:             "finance",
+		ConsulNamespace:                "billing",
+		MirroringK8S:                   true,
+		TransparentProxyDefaultEnabled: true,
+		MetricsEnabled:                 true,
+		MetricsEnableAgentMetrics:      true,
+		AuditLogEnabled:                true,
+		AuditLogSinks: []AuditSink{
+			{Name: "audit-file", Type: "file", Format: "json", Path: "/tmp/audit.json"},
+		},
+	}
+
+	got := marshalYAML(t, BuildAgentValues(cfg))
+
+	for _, want := range []string{
+		"adminPartitions",
+		"name: finance",
+		"consulDestinationNamespace: billing",
+		"mirroringK8S: true",
+		"transparentProxy",
+		"defaultEnabled: true",
+		"enableAgentMetrics: true",
+		"sinks",
+		"audit-file",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("BuildAgentValues() output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestBuildAgentValues_OptionalKnobsOmittedByDefault(t *testing.T) {
+	got := marshalYAML(t, BuildAgentValues(AgentConfig{Name: "myapp"}))
+
+	for _, notWant := range []string{"adminPartitions", "metrics", "audit", "transparentProxy"} {
+		if strings.Contains(got, notWant) {
+			t.Errorf("BuildAgentValues() output should omit unconfigured %q, got:\n%s", notWant, got)
+		}
+	}
+}
+
+func TestBuildFederationValues(t *testing.T) {
+	got := marshalYAML(t, BuildFederationValues(FederationConfig{
+		Name:              "myapp",
+		PrimaryDatacenter: "dc1",
+		K8sAuthMethodHost: "https://primary.example.com:443",
+		PrimaryGateways:   []string{"10.0.0.1:8443"},
+	}))
+
+	for _, want := range []string{
+		"primaryDatacenter: dc1",
+		"k8sAuthMethodHost: https://primary.example.com:443",
+		"- 10.0.0.1:8443",
+		"secretName: myapp-federation",
+		"secretKey: caCert",
+		"secretKey: gossipEncryptionKey",
+		"secretKey: replicationToken",
+		"meshGateway",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("BuildFederationValues() output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestBuildPeeringValues(t *testing.T) {
+	got := marshalYAML(t, BuildPeeringValues(PeeringConfig{
+		Partition: "finance",
+		Namespace: "billing",
+	}))
+
+	for _, want := range []string{
+		"peering",
+		"enabled: true",
+		"name: finance",
+		"consulDestinationNamespace: billing",
+		"meshGateway",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("BuildPeeringValues() output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestBuildPeeringValues_OmitsUnsetPartitionAndNamespace(t *testing.T) {
+	got := marshalYAML(t, BuildPeeringValues(PeeringConfig{}))
+
+	for _, notWant := range []string{"adminPartitions", "consulNamespaces"} {
+		if strings.Contains(got, notWant) {
+			t.Errorf("BuildPeeringValues() output should omit unconfigured %q, got:\n%s", notWant, got)
+		}
+	}
+}
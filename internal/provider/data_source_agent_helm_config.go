@@ -8,47 +8,12 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"sigs.k8s.io/yaml"
 
 	"github.com/hashicorp/terraform-provider-hcs/internal/clients"
+	"github.com/hashicorp/terraform-provider-hcs/internal/helm"
 )
 
-// helmConfigTemplate is the template used to generate a helm
-// config for an AKS cluster based on given inputs.
-//
-// see generateHelmConfig for details on the inputs passed in
-const helmConfigTemplate = `global:
-  enabled: false
-  name: consul
-  datacenter: %s
-  acls:
-    manageSystemACLs: true
-    bootstrapToken:
-      secretName: %s-bootstrap-token
-      secretKey: token
-  gossipEncryption:
-    secretName: %s-hcs
-    secretKey: gossipEncryptionKey
-  tls:
-    enabled: true
-    enableAutoEncrypt: true
-    caCert:
-      secretName: %s-hcs
-      secretKey: caCert
-externalServers:
-  enabled: true
-  hosts: %s
-  httpsPort: 443
-  useSystemRoots: true
-  k8sAuthMethodHost: https://%s:443
-client:
-  enabled: true
-  # If you are using Kubenet in your AKS cluster (the default network),
-  # uncomment the line below.
-  # exposeGossipPorts: true
-  join: %s
-connectInject:
-  enabled: true`
-
 // consulConfig represents the Consul config returned on the GetConfig response.
 type ConsulConfig struct {
 	Datacenter string   `json:"datacenter"`
@@ -83,11 +48,109 @@ func dataSourceAgentHelmConfig() *schema.Resource {
 				Optional:         true,
 				ValidateDiagFunc: validateStringNotEmpty,
 			},
+			"retry_join_override": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateStringNotEmpty,
+				},
+			},
+			"bootstrap_token_secret_name": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			"bootstrap_token_secret_key": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			"admin_partition_name": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			"consul_namespace": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			"mirroring_k8s": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"transparent_proxy_default_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"metrics": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Required: true,
+						},
+						"enable_agent_metrics": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"audit_log": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Required: true,
+						},
+						"sinks": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:             schema.TypeString,
+										Required:         true,
+										ValidateDiagFunc: validateStringNotEmpty,
+									},
+									"type": {
+										Type:             schema.TypeString,
+										Required:         true,
+										ValidateDiagFunc: validateStringNotEmpty,
+									},
+									"format": {
+										Type:             schema.TypeString,
+										Required:         true,
+										ValidateDiagFunc: validateStringNotEmpty,
+									},
+									"path": {
+										Type:             schema.TypeString,
+										Optional:         true,
+										ValidateDiagFunc: validateStringNotEmpty,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 			// Computed outputs
 			"config": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"values": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -142,8 +205,79 @@ func dataSourceAgentHelmConfigRead(ctx context.Context, d *schema.ResourceData,
 		return diag.Errorf("[ERROR] no AKS Cluster found for (Cluster name %q) (Resource Group %q).", aksClusterName, aksResourceGroup)
 	}
 
-	if err := d.Set("config", generateHelmConfig(
-		managedAppName, consulConfig.Datacenter, *mcResp.Fqdn, consulConfig.RetryJoin)); err != nil {
+	// retry_join_override lets a user replace the CRP-returned RetryJoin
+	// addresses with arbitrary go-discover cloud auto-join strings, for
+	// cases where the CRP-returned IPs aren't directly reachable from the
+	// AKS cluster.
+	retryJoin := consulConfig.RetryJoin
+	if v, ok := d.GetOk("retry_join_override"); ok {
+		overrides := v.([]interface{})
+		retryJoin = make([]string, 0, len(overrides))
+		for _, override := range overrides {
+			retryJoin = append(retryJoin, override.(string))
+		}
+	}
+
+	bootstrapTokenSecretName := d.Get("bootstrap_token_secret_name").(string)
+	if bootstrapTokenSecretName == "" {
+		bootstrapTokenSecretName = fmt.Sprintf("%s-bootstrap-token", strings.ToLower(managedAppName))
+	}
+	bootstrapTokenSecretKey := d.Get("bootstrap_token_secret_key").(string)
+	if bootstrapTokenSecretKey == "" {
+		bootstrapTokenSecretKey = "token"
+	}
+
+	// lowercase the name to match the format the HCS CLI is outputting
+	lowerName := strings.ToLower(managedAppName)
+
+	cfg := helm.AgentConfig{
+		Name:                           lowerName,
+		Datacenter:                     consulConfig.Datacenter,
+		Fqdn:                           *mcResp.Fqdn,
+		RetryJoin:                      retryJoin,
+		BootstrapTokenSecretName:       bootstrapTokenSecretName,
+		BootstrapTokenSecretKey:        bootstrapTokenSecretKey,
+		AdminPartitionName:             d.Get("admin_partition_name").(string),
+		ConsulNamespace:                d.Get("consul_namespace").(string),
+		MirroringK8S:                   d.Get("mirroring_k8s").(bool),
+		TransparentProxyDefaultEnabled: d.Get("transparent_proxy_default_enabled").(bool),
+	}
+
+	if v, ok := d.GetOk("metrics"); ok {
+		metrics := v.([]interface{})[0].(map[string]interface{})
+		cfg.MetricsEnabled = metrics["enabled"].(bool)
+		cfg.MetricsEnableAgentMetrics = metrics["enable_agent_metrics"].(bool)
+	}
+
+	if v, ok := d.GetOk("audit_log"); ok {
+		auditLog := v.([]interface{})[0].(map[string]interface{})
+		cfg.AuditLogEnabled = auditLog["enabled"].(bool)
+		for _, s := range auditLog["sinks"].([]interface{}) {
+			sink := s.(map[string]interface{})
+			cfg.AuditLogSinks = append(cfg.AuditLogSinks, helm.AuditSink{
+				Name:   sink["name"].(string),
+				Type:   sink["type"].(string),
+				Format: sink["format"].(string),
+				Path:   sink["path"].(string),
+			})
+		}
+	}
+
+	values := helm.BuildAgentValues(cfg)
+
+	configYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return diag.Errorf("failed to marshal helm values to YAML: %+v", err)
+	}
+	if err := d.Set("config", string(configYAML)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return diag.Errorf("failed to marshal helm values to JSON: %+v", err)
+	}
+	if err := d.Set("values", string(valuesJSON)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -151,25 +285,3 @@ func dataSourceAgentHelmConfigRead(ctx context.Context, d *schema.ResourceData,
 
 	return nil
 }
-
-// generateHelmConfig will generate a helm config based on the passed in
-// name, data center, retry join, and fqdn.
-func generateHelmConfig(name, datacenter, fqdn string, retryJoin []string) string {
-	// lowercase the name
-	lower := strings.ToLower(name)
-
-	// print retryJoin a double-quoted string safely escaped with Go syntax
-	rj := fmt.Sprintf("%q", retryJoin)
-
-	// replace any escaped double-quotes with single quotes
-	// this is to match the format the the HCS CLI is outputting
-	rj = strings.Replace(rj, "\"", "'", -1)
-
-	return fmt.Sprintf(helmConfigTemplate,
-		datacenter,
-		lower, lower, lower,
-		rj,
-		fqdn,
-		rj,
-	)
-}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/hashicorp/terraform-provider-hcs/internal/clients"
+	"github.com/hashicorp/terraform-provider-hcs/internal/helm"
+)
+
+// peeringTokenSecretTemplate is the template used to render the Kubernetes
+// Secret holding the peering token generated by the CRP for peer_name.
+//
+// see generatePeeringConfig for details on the inputs passed in
+const peeringTokenSecretTemplate = `apiVersion: v1
+kind: Secret
+metadata:
+  name: %s-peering-token
+type: Opaque
+stringData:
+  data: %s`
+
+// peeringDialerCRDTemplate is the template for the PeeringDialer CRD the
+// external cluster applies to consume the token minted by the CRP. There
+// is no companion PeeringAcceptor CRD here: the HCS-managed cluster is
+// always the accepting side, since the CRP already minted the token via
+// GeneratePeeringToken rather than waiting for a PeeringAcceptor CRD to
+// generate one. The partition a peer belongs to is carried as an
+// annotation (consul.hashicorp.com/partition), and the namespace a peer
+// belongs to as the CRD's own metadata.namespace, matching how consul-k8s
+// scopes these CRDs to a partition/namespace.
+//
+// see generatePeeringConfig for details on the inputs passed in
+const peeringDialerCRDTemplate = `apiVersion: consul.hashicorp.com/v1alpha1
+kind: PeeringDialer
+metadata:
+  name: %s%s
+spec:
+  peer:
+    secret:
+      name: %s-peering-token
+      key: data
+      backend: kubernetes`
+
+// dataSourceClusterPeering is the data source for the helm values and
+// peering token needed to peer an external Consul cluster with an
+// HCS-managed cluster, as an alternative to the mesh-gateway WAN
+// federation set up with dataSourceFederationSecret.
+func dataSourceClusterPeering() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceClusterPeeringRead,
+		Schema: map[string]*schema.Schema{
+			// Required inputs
+			"resource_group_name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateResourceGroupName,
+			},
+			"managed_application_name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateSlugID,
+			},
+			"peer_name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			// Optional
+			"partition": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			"namespace": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			// Computed outputs
+			"config": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"peering_token_secret": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"peering_dialer_crd": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// dataSourceClusterPeeringRead is the func to implement reading of the
+// cluster peering helm config and token for an HCS cluster.
+func dataSourceClusterPeeringRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	resourceGroupName := d.Get("resource_group_name").(string)
+	managedAppName := d.Get("managed_application_name").(string)
+	peerName := d.Get("peer_name").(string)
+	partition := d.Get("partition").(string)
+	namespace := d.Get("namespace").(string)
+
+	managedAppClient := meta.(*clients.Client).ManagedApplication
+	app, err := managedAppClient.Get(ctx, resourceGroupName, managedAppName)
+	if err != nil {
+		return diag.Errorf("failed to check for presence of existing HCS Cluster (Managed Application %q) (Resource Group %q): %+v", managedAppName, resourceGroupName, err)
+	}
+	if app.Response.StatusCode == 404 {
+		// No managed application exists, so returning an error stating as such
+		return diag.Errorf("[ERROR] no HCS Cluster found for (Managed Application %q) (Resource Group %q).", managedAppName, resourceGroupName)
+	}
+
+	managedAppManagedResourceGroupID := *app.ManagedResourceGroupID
+
+	crpClient := meta.(*clients.Client).CustomResourceProvider
+
+	// GeneratePeeringToken is a CRP action distinct from Config/Secrets: it
+	// mutates the primary by minting a token scoped to peerName (and, if
+	// given, partition/namespace), rather than reading existing state.
+	// This is new CRP client surface this data source depends on; it is
+	// not added under internal/clients by this change set, since that
+	// package's source isn't part of this tree.
+	tokenResp, err := crpClient.GeneratePeeringToken(ctx, managedAppManagedResourceGroupID, peerName, partition, namespace)
+	if err != nil {
+		return diag.Errorf("failed to generate peering token for managed app: %+v", err)
+	}
+
+	config, tokenSecret, dialerCRD, err := generatePeeringConfig(managedAppName, peerName, partition, namespace, tokenResp.PeeringToken)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("config", config); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("peering_token_secret", tokenSecret); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("peering_dialer_crd", dialerCRD); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(*app.ID + "/cluster-peering/" + peerName)
+
+	return nil
+}
+
+// generatePeeringConfig will generate the peering helm config, token
+// Secret manifest, and dialer CRD snippet based on the passed in name,
+// peer name, partition, namespace, and peering token.
+func generatePeeringConfig(name, peerName, partition, namespace, peeringToken string) (config, tokenSecret, dialerCRD string, err error) {
+	// lowercase the name and peer name to match the convention used by
+	// dataSourceAgentHelmConfigRead
+	lower := strings.ToLower(name)
+	lowerPeer := strings.ToLower(peerName)
+
+	values := helm.BuildPeeringValues(helm.PeeringConfig{
+		Partition: partition,
+		Namespace: namespace,
+	})
+
+	configYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to marshal peering helm values to YAML: %+v", err)
+	}
+
+	tokenSecret = fmt.Sprintf(peeringTokenSecretTemplate, lowerPeer, peeringToken)
+	dialerCRD = fmt.Sprintf(peeringDialerCRDTemplate, lower, crdMetadataExtra(partition, namespace), lowerPeer)
+
+	return string(configYAML), tokenSecret, dialerCRD, nil
+}
+
+// crdMetadataExtra renders the namespace/partition metadata that scopes
+// the PeeringDialer CRD, for splicing into peeringDialerCRDTemplate's
+// metadata block. Consul CRDs are scoped to a namespace via the
+// Kubernetes object's own metadata.namespace, and to a partition via the
+// consul.hashicorp.com/partition annotation.
+func crdMetadataExtra(partition, namespace string) string {
+	var b strings.Builder
+
+	if namespace != "" {
+		fmt.Fprintf(&b, "\n  namespace: %s", strings.ToLower(namespace))
+	}
+	if partition != "" {
+		fmt.Fprintf(&b, "\n  annotations:\n    consul.hashicorp.com/partition: %s", strings.ToLower(partition))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCrdMetadataExtra(t *testing.T) {
+	cases := map[string]struct {
+		partition string
+		namespace string
+		want      string
+	}{
+		"neither set": {
+			want: "",
+		},
+		"namespace only": {
+			namespace: "Billing",
+			want:      "\n  namespace: billing",
+		},
+		"partition only": {
+			partition: "Finance",
+			want:      "\n  annotations:\n    consul.hashicorp.com/partition: finance",
+		},
+		"both set": {
+			partition: "Finance",
+			namespace: "Billing",
+			want:      "\n  namespace: billing\n  annotations:\n    consul.hashicorp.com/partition: finance",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := crdMetadataExtra(tc.partition, tc.namespace)
+			if got != tc.want {
+				t.Errorf("crdMetadataExtra(%q, %q) = %q, want %q", tc.partition, tc.namespace, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePeeringConfig(t *testing.T) {
+	config, tokenSecret, dialerCRD, err := generatePeeringConfig("MyApp", "MyPeer", "", "", "sometoken==")
+	if err != nil {
+		t.Fatalf("generatePeeringConfig() returned error: %v", err)
+	}
+
+	if !strings.Contains(config, "peering:\n    enabled: true") {
+		t.Errorf("config missing global.peering.enabled, got: %s", config)
+	}
+	if !strings.Contains(tokenSecret, "name: mypeer-peering-token") || !strings.Contains(tokenSecret, "data: sometoken==") {
+		t.Errorf("peering token secret malformed, got: %s", tokenSecret)
+	}
+	if !strings.Contains(dialerCRD, "kind: PeeringDialer") || !strings.Contains(dialerCRD, "name: mypeer-peering-token") {
+		t.Errorf("dialer CRD malformed, got: %s", dialerCRD)
+	}
+}
+
+func TestGeneratePeeringConfig_PartitionAndNamespace(t *testing.T) {
+	config, _, dialerCRD, err := generatePeeringConfig("myapp", "mypeer", "finance", "billing", "sometoken==")
+	if err != nil {
+		t.Fatalf("generatePeeringConfig() returned error: %v", err)
+	}
+
+	if !strings.Contains(config, "name: finance") {
+		t.Errorf("config did not thread partition into adminPartitions, got: %s", config)
+	}
+	if !strings.Contains(config, "consulDestinationNamespace: billing") {
+		t.Errorf("config did not thread namespace into consulNamespaces, got: %s", config)
+	}
+	if !strings.Contains(dialerCRD, "namespace: billing") {
+		t.Errorf("dialer CRD did not thread namespace into metadata, got: %s", dialerCRD)
+	}
+	if !strings.Contains(dialerCRD, "consul.hashicorp.com/partition: finance") {
+		t.Errorf("dialer CRD did not thread partition into annotations, got: %s", dialerCRD)
+	}
+}
@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/hashicorp/terraform-provider-hcs/internal/clients"
+	"github.com/hashicorp/terraform-provider-hcs/internal/helm"
+)
+
+// federationSecretTemplate is the template used to render the Kubernetes
+// Secret a secondary Consul datacenter needs in order to federate with an
+// HCS-managed primary, mirroring the Secret produced by consul-k8s's
+// create-federation-secret job.
+//
+// see generateFederationSecret for details on the inputs passed in
+const federationSecretTemplate = `apiVersion: v1
+kind: Secret
+metadata:
+  name: %s-federation
+type: Opaque
+stringData:
+  caCert: |
+%s
+  caKey: |
+%s
+  gossipEncryptionKey: %s
+  replicationToken: %s
+  serverConfigJSON: '%s'`
+
+// FederationConfig represents the subset of the Consul config returned on
+// the GetConfig response that is relevant to federating a secondary
+// datacenter with an HCS-managed primary: the primary's datacenter name
+// and its mesh gateway addresses/ports.
+type FederationConfig struct {
+	Datacenter      string   `json:"datacenter"`
+	PrimaryGateways []string `json:"primary_gateways"`
+}
+
+// FederationSecrets represents the secrets a secondary datacenter needs in
+// order to federate with an HCS-managed primary, returned by the CRP's
+// secrets endpoint (distinct from GetConfig, since these are sensitive
+// values rather than cluster configuration).
+type FederationSecrets struct {
+	CACert              string `json:"ca_cert"`
+	CAKey               string `json:"ca_key"`
+	GossipEncryptionKey string `json:"gossip_key"`
+	// ReplicationToken is only populated when ACLs are enabled on the
+	// primary.
+	ReplicationToken string `json:"replication_token"`
+}
+
+// dataSourceFederationSecret is the data source for the Kubernetes Secret
+// manifest a secondary Consul datacenter needs to federate with an
+// HCS-managed primary.
+func dataSourceFederationSecret() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFederationSecretRead,
+		Schema: map[string]*schema.Schema{
+			// Required inputs
+			"resource_group_name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateResourceGroupName,
+			},
+			"managed_application_name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateSlugID,
+			},
+			// Optional
+			//
+			// aks_cluster_name/aks_resource_group identify the primary's
+			// AKS cluster so its Kubernetes API server endpoint can be
+			// used for global.federation.k8sAuthMethodHost. If omitted,
+			// k8sAuthMethodHost is left out of federation_helm_values
+			// rather than guessed from a mesh gateway address.
+			"aks_cluster_name": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			"aks_resource_group": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateStringNotEmpty,
+			},
+			// Computed outputs
+			"secret": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"federation_helm_values": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// dataSourceFederationSecretRead is the func to implement reading of the
+// federation secret for an HCS cluster.
+func dataSourceFederationSecretRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	resourceGroupName := d.Get("resource_group_name").(string)
+	managedAppName := d.Get("managed_application_name").(string)
+
+	managedAppClient := meta.(*clients.Client).ManagedApplication
+	app, err := managedAppClient.Get(ctx, resourceGroupName, managedAppName)
+	if err != nil {
+		return diag.Errorf("failed to check for presence of existing HCS Cluster (Managed Application %q) (Resource Group %q): %+v", managedAppName, resourceGroupName, err)
+	}
+	if app.Response.StatusCode == 404 {
+		// No managed application exists, so returning an error stating as such
+		return diag.Errorf("[ERROR] no HCS Cluster found for (Managed Application %q) (Resource Group %q).", managedAppName, resourceGroupName)
+	}
+
+	managedAppManagedResourceGroupID := *app.ManagedResourceGroupID
+
+	crpClient := meta.(*clients.Client).CustomResourceProvider
+
+	resp, err := crpClient.Config(ctx, managedAppManagedResourceGroupID)
+	if err != nil {
+		return diag.Errorf("failed to get config for managed app: %+v", err)
+	}
+
+	var federationConfig FederationConfig
+	err = json.Unmarshal([]byte(resp.ClientConfig), &federationConfig)
+	if err != nil {
+		return diag.Errorf("failed to json unmarshal Consul config %v", err)
+	}
+
+	// The CA cert, gossip encryption key, and (if ACLs are enabled)
+	// replication token are sensitive values the CRP serves from a
+	// dedicated secrets endpoint rather than GetConfig. Secrets is new
+	// CRP client surface this data source depends on; it is not added
+	// under internal/clients by this change set, since that package's
+	// source isn't part of this tree.
+	secretsResp, err := crpClient.Secrets(ctx, managedAppManagedResourceGroupID)
+	if err != nil {
+		return diag.Errorf("failed to get secrets for managed app: %+v", err)
+	}
+
+	var federationSecrets FederationSecrets
+	err = json.Unmarshal([]byte(secretsResp.Secrets), &federationSecrets)
+	if err != nil {
+		return diag.Errorf("failed to json unmarshal Consul secrets %v", err)
+	}
+
+	// k8sAuthMethodHost must be the primary datacenter's Kubernetes API
+	// server endpoint, not a Consul mesh gateway address, so it is only
+	// populated when the primary's AKS cluster is identified.
+	var k8sAuthMethodHost string
+	if aksClusterName := d.Get("aks_cluster_name").(string); aksClusterName != "" {
+		aksResourceGroup := d.Get("aks_resource_group").(string)
+		if aksResourceGroup == "" {
+			aksResourceGroup = resourceGroupName
+		}
+
+		mcClient := meta.(*clients.Client).ManagedClusters
+		mcResp, err := mcClient.Get(ctx, aksResourceGroup, aksClusterName)
+		if err != nil {
+			return diag.Errorf("failed to check for presence of existing AKS Cluster (Cluster name %q) (Resource Group %q): %+v", aksClusterName, aksResourceGroup, err)
+		}
+		if mcResp.Response.StatusCode == 404 {
+			// No AKS cluster exists, so returning an error stating as such
+			return diag.Errorf("[ERROR] no AKS Cluster found for (Cluster name %q) (Resource Group %q).", aksClusterName, aksResourceGroup)
+		}
+
+		k8sAuthMethodHost = fmt.Sprintf("https://%s:443", *mcResp.Fqdn)
+	}
+
+	secret, helmValues, err := generateFederationSecret(managedAppName, federationConfig, federationSecrets, k8sAuthMethodHost)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("secret", secret); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("federation_helm_values", helmValues); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(*app.ID + "/federation-secret")
+
+	return nil
+}
+
+// generateFederationSecret will generate the federation Secret manifest and
+// companion helm values snippet based on the passed in name, federation
+// config and secrets returned by the CRP, and (if known) the primary's
+// k8s auth method host.
+func generateFederationSecret(name string, cfg FederationConfig, secrets FederationSecrets, k8sAuthMethodHost string) (secret string, helmValues string, err error) {
+	// lowercase the name to match the convention used by
+	// dataSourceAgentHelmConfigRead
+	lower := strings.ToLower(name)
+
+	serverConfig, err := json.Marshal(map[string]interface{}{
+		"primary_datacenter": cfg.Datacenter,
+		"primary_gateways":   cfg.PrimaryGateways,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to json marshal server config: %+v", err)
+	}
+
+	secret = fmt.Sprintf(federationSecretTemplate,
+		lower,
+		indentPEM(secrets.CACert),
+		indentPEM(secrets.CAKey),
+		secrets.GossipEncryptionKey,
+		secrets.ReplicationToken,
+		string(serverConfig),
+	)
+
+	values := helm.BuildFederationValues(helm.FederationConfig{
+		Name:              lower,
+		PrimaryDatacenter: cfg.Datacenter,
+		K8sAuthMethodHost: k8sAuthMethodHost,
+		PrimaryGateways:   cfg.PrimaryGateways,
+	})
+
+	helmValuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal federation helm values to YAML: %+v", err)
+	}
+
+	return secret, string(helmValuesYAML), nil
+}
+
+// indentPEM indents a PEM-encoded value so it nests correctly under the
+// block scalar fields of federationSecretTemplate.
+func indentPEM(pem string) string {
+	if pem == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSpace(pem), "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndentPEM(t *testing.T) {
+	cases := map[string]struct {
+		in   string
+		want string
+	}{
+		"empty": {
+			in:   "",
+			want: "",
+		},
+		"single line": {
+			in:   "ABCDEF",
+			want: "    ABCDEF",
+		},
+		"multi line with surrounding whitespace": {
+			in:   "\n-----BEGIN CERTIFICATE-----\nABCDEF\n-----END CERTIFICATE-----\n",
+			want: "    -----BEGIN CERTIFICATE-----\n    ABCDEF\n    -----END CERTIFICATE-----",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := indentPEM(tc.in)
+			if got != tc.want {
+				t.Errorf("indentPEM(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerateFederationSecret(t *testing.T) {
+	cfg := FederationConfig{
+		Datacenter:      "dc1",
+		PrimaryGateways: []string{"10.0.0.1:8443", "10.0.0.2:8443"},
+	}
+	secrets := FederationSecrets{
+		CACert:              "-----BEGIN CERTIFICATE-----\nABCDEF\n-----END CERTIFICATE-----",
+		GossipEncryptionKey: "gossipkey==",
+		ReplicationToken:    "replicationtoken",
+	}
+
+	secret, helmValues, err := generateFederationSecret("MyApp", cfg, secrets, "https://primary.example.com:443")
+	if err != nil {
+		t.Fatalf("generateFederationSecret() returned error: %v", err)
+	}
+
+	if !strings.Contains(secret, "name: myapp-federation") {
+		t.Errorf("secret missing lowercased name, got: %s", secret)
+	}
+	if !strings.Contains(secret, "    -----BEGIN CERTIFICATE-----") {
+		t.Errorf("secret caCert not indented as a block scalar, got: %s", secret)
+	}
+	if !strings.Contains(secret, "gossipEncryptionKey: gossipkey==") {
+		t.Errorf("secret missing gossipEncryptionKey, got: %s", secret)
+	}
+	if !strings.Contains(secret, `"primary_gateways":["10.0.0.1:8443","10.0.0.2:8443"]`) {
+		t.Errorf("secret serverConfigJSON missing primary_gateways, got: %s", secret)
+	}
+
+	// primaryGateways must render as a real YAML list, not a space
+	// separated flow sequence like ['a:8443' 'b:8443'].
+	if !strings.Contains(helmValues, "- 10.0.0.1:8443") || !strings.Contains(helmValues, "- 10.0.0.2:8443") {
+		t.Errorf("federation helm values did not render primaryGateways as a YAML list, got: %s", helmValues)
+	}
+	if !strings.Contains(helmValues, "k8sAuthMethodHost: https://primary.example.com:443") {
+		t.Errorf("federation helm values missing k8sAuthMethodHost, got: %s", helmValues)
+	}
+	if !strings.Contains(helmValues, "secretName: myapp-federation") {
+		t.Errorf("federation helm values do not point at the federation secret, got: %s", helmValues)
+	}
+}
+
+func TestGenerateFederationSecret_NoK8sAuthMethodHost(t *testing.T) {
+	_, helmValues, err := generateFederationSecret("myapp", FederationConfig{Datacenter: "dc1"}, FederationSecrets{}, "")
+	if err != nil {
+		t.Fatalf("generateFederationSecret() returned error: %v", err)
+	}
+
+	if strings.Contains(helmValues, "k8sAuthMethodHost") {
+		t.Errorf("k8sAuthMethodHost should be omitted when not known, got: %s", helmValues)
+	}
+}